@@ -75,6 +75,9 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 		bastionCtrlOpts = &controllercmd.ControllerOptions{
 			MaxConcurrentReconciles: 5,
 		}
+		// options for the bastion controller's TTL enforcement loop
+		bastionTTLCtrlOpts        = &gcpbastion.TTLOptions{}
+		bastionCtrlOptsUnprefixed = controllercmd.NewOptionAggregator(bastionCtrlOpts, bastionTTLCtrlOpts)
 
 		// options for the health care controller
 		healthCheckCtrlOpts = &controllercmd.ControllerOptions{
@@ -125,7 +128,7 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 			mgrOpts,
 			controllercmd.PrefixOption("backupbucket-", backupBucketCtrlOpts),
 			controllercmd.PrefixOption("backupentry-", backupEntryCtrlOpts),
-			controllercmd.PrefixOption("bastion-", bastionCtrlOpts),
+			controllercmd.PrefixOption("bastion-", &bastionCtrlOptsUnprefixed),
 			controllercmd.PrefixOption("controlplane-", controlPlaneCtrlOpts),
 			controllercmd.PrefixOption("csimigration-", csiMigrationCtrlOpts),
 			controllercmd.PrefixOption("dnsrecord-", dnsRecordCtrlOpts),
@@ -186,6 +189,7 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 			backupBucketCtrlOpts.Completed().Apply(&gcpbackupbucket.DefaultAddOptions.Controller)
 			backupEntryCtrlOpts.Completed().Apply(&gcpbackupentry.DefaultAddOptions.Controller)
 			bastionCtrlOpts.Completed().Apply(&gcpbastion.DefaultAddOptions.Controller)
+			bastionTTLCtrlOpts.Completed().Apply(&gcpbastion.DefaultAddOptions.TTL)
 			controlPlaneCtrlOpts.Completed().Apply(&gcpcontrolplane.DefaultAddOptions.Controller)
 			csiMigrationCtrlOpts.Completed().Apply(&gcpcsimigration.DefaultAddOptions.Controller)
 			dnsRecordCtrlOpts.Completed().Apply(&gcpdnsrecord.DefaultAddOptions.Controller)
@@ -204,6 +208,10 @@ func NewControllerManagerCommand(ctx context.Context) *cobra.Command {
 				controllercmd.LogErrAndExit(err, "Could not add controllers to manager")
 			}
 
+			if err := gcpbastion.AddTTLRunnable(mgr, gcpbastion.DefaultAddOptions.TTL.CheckPeriod); err != nil {
+				controllercmd.LogErrAndExit(err, "Could not add bastion TTL runnable to manager")
+			}
+
 			if err := mgr.Start(ctx); err != nil {
 				controllercmd.LogErrAndExit(err, "Error running manager")
 			}
@@ -0,0 +1,77 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gcp
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// BastionConfig contains provider-specific configuration for a Bastion's
+// compute instance. It is embedded in the Bastion resource's
+// `spec.providerConfig`. Fields left unset fall back to the extension's
+// built-in defaults.
+type BastionConfig struct {
+	metav1.TypeMeta
+
+	// MachineType is the GCP machine type to use for the bastion compute
+	// instance, e.g. "n1-standard-1". Defaults to "n1-standard-1".
+	// +optional
+	MachineType *string
+	// Image is the boot image for the bastion, given either as an image
+	// family (e.g. "debian-10") or a fully-qualified image self-link.
+	// Defaults to the "debian-10" image family.
+	// +optional
+	Image *string
+	// DiskSizeGB is the size of the bastion's boot disk in GB.
+	// Defaults to 10.
+	// +optional
+	DiskSizeGB *int32
+	// DiskType is the GCP disk type to use for the bastion's boot disk, one
+	// of "pd-standard", "pd-balanced" or "pd-ssd". Defaults to "pd-standard".
+	// +optional
+	DiskType *string
+	// Labels are additional labels to apply to the bastion compute instance.
+	// +optional
+	Labels map[string]string
+	// ShieldedInstanceConfig configures the bastion's shielded VM options.
+	// Defaults to secure boot, vTPM and integrity monitoring all enabled.
+	// +optional
+	ShieldedInstanceConfig *ShieldedInstanceConfig
+	// ConfidentialCompute enables Confidential Computing (AMD SEV) on the
+	// bastion compute instance. It requires a machine type from the N2D or
+	// C2D families and an image that supports confidential compute.
+	// Defaults to false.
+	// +optional
+	ConfidentialCompute *bool
+}
+
+// ShieldedInstanceConfig configures the shielded VM options of a bastion
+// compute instance.
+type ShieldedInstanceConfig struct {
+	// EnableSecureBoot defines whether the instance should have secure boot
+	// enabled. Defaults to true.
+	// +optional
+	EnableSecureBoot *bool
+	// EnableVtpm defines whether the instance should have the virtual
+	// trusted platform module enabled. Defaults to true.
+	// +optional
+	EnableVtpm *bool
+	// EnableIntegrityMonitoring defines whether the instance should have
+	// integrity monitoring enabled. Defaults to true.
+	// +optional
+	EnableIntegrityMonitoring *bool
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// DefaultBastionMachineType is used whenever BastionConfig.MachineType is unset.
+	DefaultBastionMachineType = "n1-standard-1"
+	// DefaultBastionImage is used whenever BastionConfig.Image is unset.
+	DefaultBastionImage = "debian-10"
+	// DefaultBastionDiskSizeGB is used whenever BastionConfig.DiskSizeGB is unset.
+	DefaultBastionDiskSizeGB = int32(10)
+	// DefaultBastionDiskType is used whenever BastionConfig.DiskType is unset.
+	DefaultBastionDiskType = "pd-standard"
+)
+
+// SetDefaults_BastionConfig sets default values for BastionConfig objects.
+func SetDefaults_BastionConfig(obj *BastionConfig) {
+	if obj.MachineType == nil {
+		obj.MachineType = pointer.String(DefaultBastionMachineType)
+	}
+	if obj.Image == nil {
+		obj.Image = pointer.String(DefaultBastionImage)
+	}
+	if obj.DiskSizeGB == nil {
+		obj.DiskSizeGB = pointer.Int32(DefaultBastionDiskSizeGB)
+	}
+	if obj.DiskType == nil {
+		obj.DiskType = pointer.String(DefaultBastionDiskType)
+	}
+	if obj.ShieldedInstanceConfig == nil {
+		obj.ShieldedInstanceConfig = &ShieldedInstanceConfig{}
+	}
+	if obj.ShieldedInstanceConfig.EnableSecureBoot == nil {
+		obj.ShieldedInstanceConfig.EnableSecureBoot = pointer.Bool(true)
+	}
+	if obj.ShieldedInstanceConfig.EnableVtpm == nil {
+		obj.ShieldedInstanceConfig.EnableVtpm = pointer.Bool(true)
+	}
+	if obj.ShieldedInstanceConfig.EnableIntegrityMonitoring == nil {
+		obj.ShieldedInstanceConfig.EnableIntegrityMonitoring = pointer.Bool(true)
+	}
+	if obj.ConfidentialCompute == nil {
+		obj.ConfidentialCompute = pointer.Bool(false)
+	}
+}
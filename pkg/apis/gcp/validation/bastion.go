@@ -0,0 +1,64 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"regexp"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/gardener/gardener-extension-provider-gcp/pkg/apis/gcp"
+)
+
+var availableBastionDiskTypes = sets.NewString("pd-standard", "pd-balanced", "pd-ssd")
+
+// confidentialComputeMachineFamilies matches the machine type families that
+// support Confidential Computing (AMD SEV), e.g. "n2d-standard-2" or
+// "c2d-highcpu-4".
+var confidentialComputeMachineFamilies = regexp.MustCompile(`^(n2d|c2d)-`)
+
+// ValidateBastionConfig validates a BastionConfig object.
+func ValidateBastionConfig(config *gcp.BastionConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if config == nil {
+		return allErrs
+	}
+
+	if config.DiskSizeGB != nil && *config.DiskSizeGB <= 0 {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("diskSizeGB"), *config.DiskSizeGB, "must be greater than 0"))
+	}
+
+	if config.DiskType != nil && !availableBastionDiskTypes.Has(*config.DiskType) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("diskType"), *config.DiskType, availableBastionDiskTypes.List()))
+	}
+
+	if config.MachineType != nil && *config.MachineType == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("machineType"), *config.MachineType, "must not be empty"))
+	}
+
+	if config.Image != nil && *config.Image == "" {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("image"), *config.Image, "must not be empty"))
+	}
+
+	if config.ConfidentialCompute != nil && *config.ConfidentialCompute &&
+		config.MachineType != nil && !confidentialComputeMachineFamilies.MatchString(*config.MachineType) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("confidentialCompute"), *config.MachineType,
+			"confidential compute is only supported on N2D and C2D machine types"))
+	}
+
+	return allErrs
+}
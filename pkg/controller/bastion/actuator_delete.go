@@ -0,0 +1,65 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+func (a *actuator) Delete(ctx context.Context, bastion *extensionsv1alpha1.Bastion, cluster *controller.Cluster) error {
+	logger := a.logger.WithValues("bastion", bastion.Name, "operation", "delete")
+
+	serviceAccount, err := getServiceAccount(ctx, a, bastion)
+	if err != nil {
+		return fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	gcpClient, err := createGCPClient(ctx, serviceAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create GCP client: %w", err)
+	}
+
+	opt, err := DetermineOptions(bastion, cluster, serviceAccount.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to determine Options: %w", err)
+	}
+
+	if opt.SSHAccessMode == sshAccessModeOSLogin {
+		if err := revokeOSLoginBinding(ctx, a.Client(), bastion, gcpClient, opt); err != nil {
+			return fmt.Errorf("failed to revoke OS Login access: %w", err)
+		}
+	}
+
+	logger.Info("Deleting bastion compute instance")
+	if err := deleteComputeInstance(ctx, gcpClient, opt); err != nil {
+		return fmt.Errorf("failed to delete bastion compute instance: %w", err)
+	}
+
+	logger.Info("Deleting bastion compute instance disk")
+	if err := deleteDisk(ctx, gcpClient, opt); err != nil {
+		return fmt.Errorf("failed to delete bastion compute instance disk: %w", err)
+	}
+
+	logger.Info("Deleting bastion firewall rules")
+	if err := deleteFirewallRules(ctx, gcpClient, opt); err != nil {
+		return fmt.Errorf("failed to delete bastion firewall rules: %w", err)
+	}
+
+	return nil
+}
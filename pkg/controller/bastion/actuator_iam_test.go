@@ -0,0 +1,178 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newFakeGCPClient(bindings ...*cloudresourcemanager.Binding) *fakeGCPClient {
+	return &fakeGCPClient{
+		projectIAM: &fakeProjectIAM{
+			policy: &cloudresourcemanager.Policy{Etag: "initial", Bindings: bindings},
+		},
+	}
+}
+
+func memberOf(binding *cloudresourcemanager.Binding, member string) bool {
+	for _, m := range binding.Members {
+		if m == member {
+			return true
+		}
+	}
+	return false
+}
+
+func bindingWithRole(policy *cloudresourcemanager.Policy, role string) *cloudresourcemanager.Binding {
+	for _, b := range policy.Bindings {
+		if b.Role == role {
+			return b
+		}
+	}
+	return nil
+}
+
+func TestEnsureOSLoginBinding(t *testing.T) {
+	ctx := context.Background()
+	cli := newFakeGCPClient()
+	opt := &Options{ProjectID: "my-project", OSLoginUser: "jane@example.com"}
+
+	if err := ensureOSLoginBinding(ctx, cli, opt); err != nil {
+		t.Fatalf("ensureOSLoginBinding returned error: %v", err)
+	}
+
+	binding := bindingWithRole(cli.projectIAM.policy, osLoginRole)
+	if binding == nil || !memberOf(binding, "user:jane@example.com") {
+		t.Fatalf("expected %s to be bound to jane@example.com, got %+v", osLoginRole, cli.projectIAM.policy.Bindings)
+	}
+
+	// calling again must not duplicate the member
+	if err := ensureOSLoginBinding(ctx, cli, opt); err != nil {
+		t.Fatalf("ensureOSLoginBinding returned error on second call: %v", err)
+	}
+	binding = bindingWithRole(cli.projectIAM.policy, osLoginRole)
+	if len(binding.Members) != 1 {
+		t.Fatalf("expected member to be added only once, got %v", binding.Members)
+	}
+}
+
+func TestEnsureOSLoginBindingRetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	cli := newFakeGCPClient()
+	cli.projectIAM.conflictsBeforeSuccess = 2
+	opt := &Options{ProjectID: "my-project", OSLoginUser: "jane@example.com", OSLoginAdmin: true}
+
+	if err := ensureOSLoginBinding(ctx, cli, opt); err != nil {
+		t.Fatalf("ensureOSLoginBinding returned error: %v", err)
+	}
+	if cli.projectIAM.setPolicyCalls != 3 {
+		t.Fatalf("expected 3 SetPolicy attempts (2 conflicts + 1 success), got %d", cli.projectIAM.setPolicyCalls)
+	}
+	if bindingWithRole(cli.projectIAM.policy, osLoginAdminRole) == nil {
+		t.Fatalf("expected admin role to be bound, got %+v", cli.projectIAM.policy.Bindings)
+	}
+}
+
+func newFakeBastionClient(t *testing.T, bastions ...*extensionsv1alpha1.Bastion) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := controller.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to build scheme: %v", err)
+	}
+	objs := make([]client.Object, 0, len(bastions))
+	for _, b := range bastions {
+		objs = append(objs, b)
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+}
+
+func TestRevokeOSLoginBinding(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{ProjectID: "my-project", OSLoginUser: "jane@example.com"}
+	bastion := &extensionsv1alpha1.Bastion{ObjectMeta: metav1.ObjectMeta{Name: "b1", Namespace: "shoot--foo"}}
+
+	cli := newFakeGCPClient(&cloudresourcemanager.Binding{Role: osLoginRole, Members: []string{"user:jane@example.com"}})
+	k8sClient := newFakeBastionClient(t, bastion)
+
+	if err := revokeOSLoginBinding(ctx, k8sClient, bastion, cli, opt); err != nil {
+		t.Fatalf("revokeOSLoginBinding returned error: %v", err)
+	}
+
+	binding := bindingWithRole(cli.projectIAM.policy, osLoginRole)
+	if binding != nil && memberOf(binding, "user:jane@example.com") {
+		t.Fatalf("expected jane@example.com to be unbound, got %+v", cli.projectIAM.policy.Bindings)
+	}
+}
+
+func TestRevokeOSLoginBindingKeepsSharedAccess(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{ProjectID: "my-project", OSLoginUser: "jane@example.com"}
+
+	deleting := &extensionsv1alpha1.Bastion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "b1", Namespace: "shoot--foo",
+			Annotations: map[string]string{annotationOSLoginUser: "jane@example.com"},
+		},
+	}
+	other := &extensionsv1alpha1.Bastion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "b2", Namespace: "shoot--foo",
+			Annotations: map[string]string{annotationOSLoginUser: "jane@example.com"},
+		},
+	}
+
+	cli := newFakeGCPClient(&cloudresourcemanager.Binding{Role: osLoginRole, Members: []string{"user:jane@example.com"}})
+	k8sClient := newFakeBastionClient(t, deleting, other)
+
+	if err := revokeOSLoginBinding(ctx, k8sClient, deleting, cli, opt); err != nil {
+		t.Fatalf("revokeOSLoginBinding returned error: %v", err)
+	}
+
+	binding := bindingWithRole(cli.projectIAM.policy, osLoginRole)
+	if binding == nil || !memberOf(binding, "user:jane@example.com") {
+		t.Fatalf("expected jane@example.com to remain bound while bastion %q is still live, got %+v", other.Name, cli.projectIAM.policy.Bindings)
+	}
+}
+
+func TestEnsureIAPTunnelAccess(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{ProjectID: "my-project", ServiceAccountEmail: "sa@my-project.iam.gserviceaccount.com"}
+
+	t.Run("missing role", func(t *testing.T) {
+		cli := newFakeGCPClient()
+		if err := ensureIAPTunnelAccess(ctx, cli, opt); err == nil {
+			t.Fatal("expected an error when the service account is missing the IAP role")
+		}
+	})
+
+	t.Run("role already bound", func(t *testing.T) {
+		cli := newFakeGCPClient(&cloudresourcemanager.Binding{
+			Role:    iapTunnelResourceAccessorRole,
+			Members: []string{"serviceAccount:sa@my-project.iam.gserviceaccount.com"},
+		})
+		if err := ensureIAPTunnelAccess(ctx, cli, opt); err != nil {
+			t.Fatalf("ensureIAPTunnelAccess returned error: %v", err)
+		}
+	})
+}
@@ -16,17 +16,21 @@ package bastion
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"reflect"
+	"strings"
 	"time"
 
 	gcpclient "github.com/gardener/gardener-extension-provider-gcp/pkg/internal/client"
 
 	"github.com/gardener/gardener/extensions/pkg/controller"
 	ctrlerror "github.com/gardener/gardener/extensions/pkg/controller/error"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/go-logr/logr"
+	"google.golang.org/api/cloudresourcemanager/v1"
 	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/util/retry"
@@ -34,10 +38,22 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// iapSourceRange is the CIDR range Google reserves for IAP TCP forwarding;
+// when a bastion is operated in IAP mode, ingress SSH is restricted to this
+// range instead of the CIDRs supplied by the user.
+// See https://cloud.google.com/iap/docs/using-tcp-forwarding#create-firewall-rule.
+const iapSourceRange = "35.235.240.0/20"
+
+// iapTunnelResourceAccessorRole is the IAM role the bastion's service account
+// needs in order for IAP to be allowed to open a tunnel to the instance.
+const iapTunnelResourceAccessorRole = "roles/iap.tunnelResourceAccessor"
+
 // bastionEndpoints collects the endpoints the bastion host provides; the
 // private endpoint is important for opening a port on the worker node
 // ingress firewall rule to allow SSH from that node, the public endpoint is where
-// the end user connects to establish the SSH connection.
+// the end user connects to establish the SSH connection. In IAP mode the
+// "public" endpoint is in fact the instance's internal IP, since there is no
+// external NAT to publish.
 type bastionEndpoints struct {
 	private *corev1.LoadBalancerIngress
 	public  *corev1.LoadBalancerIngress
@@ -74,6 +90,18 @@ func (a *actuator) Reconcile(ctx context.Context, bastion *extensionsv1alpha1.Ba
 		}
 	}
 
+	if opt.PrivateBastion {
+		if err := ensureIAPTunnelAccess(ctx, gcpClient, opt); err != nil {
+			return fmt.Errorf("failed to ensure IAP tunnel access for bastion service account: %w", err)
+		}
+	}
+
+	if opt.SSHAccessMode == sshAccessModeOSLogin {
+		if err := ensureOSLoginBinding(ctx, gcpClient, opt); err != nil {
+			return fmt.Errorf("failed to grant OS Login access: %w", err)
+		}
+	}
+
 	err = controller.TryUpdateStatus(ctx, retry.DefaultBackoff, a.Client(), bastion, func() error {
 		bytes, err := marshalProviderStatus(opt.Zone)
 		if err != nil {
@@ -97,12 +125,20 @@ func (a *actuator) Reconcile(ctx context.Context, bastion *extensionsv1alpha1.Ba
 	}
 
 	instance, err := ensureComputeInstance(ctx, logger, bastion, gcpClient, opt)
+	if errors.Is(err, errInstanceExternallyDeleted) {
+		return controller.TryUpdateStatus(ctx, retry.DefaultBackoff, a.Client(), bastion, func() error {
+			bastion.Status.LastError = &gardencorev1beta1.LastError{
+				Description: errInstanceExternallyDeleted.Error(),
+			}
+			return nil
+		})
+	}
 	if err != nil {
 		return err
 	}
 
 	// check if the instance already exists and has an IP
-	endpoints, err := getInstanceEndpoints(instance)
+	endpoints, err := getInstanceEndpoints(instance, opt.PrivateBastion)
 	if err != nil {
 		return err
 	}
@@ -116,6 +152,11 @@ func (a *actuator) Reconcile(ctx context.Context, bastion *extensionsv1alpha1.Ba
 		}
 	}
 
+	if opt.PrivateBastion {
+		a.Recorder().Eventf(bastion, corev1.EventTypeNormal, "IAPTunnelRequired",
+			"published address is the bastion's internal IP; connect to it through an IAP TCP-forwarding tunnel, not directly")
+	}
+
 	// once a public endpoint is available, publish the endpoint on the
 	// Bastion resource to notify upstream about the ready instance
 	return controller.TryUpdateStatus(ctx, retry.DefaultBackoff, a.Client(), bastion, func() error {
@@ -125,36 +166,236 @@ func (a *actuator) Reconcile(ctx context.Context, bastion *extensionsv1alpha1.Ba
 
 }
 
-func ensureFirewallRules(ctx context.Context, gcpclient gcpclient.Interface, opt *Options) error {
-	firewallList := []*compute.Firewall{IngressAllowSSH(opt), EgressDenyAll(opt), EgressAllowOnly(opt)}
+// ensureIAPTunnelAccess checks that the project's IAM policy grants the
+// bastion's service account the iap.tunnelResourceAccessor role, which is
+// required for Identity-Aware Proxy to open a tunnel to a private bastion
+// instance. It does not attempt to bind the role itself, since doing so
+// would require project-level IAM permissions the extension is not
+// guaranteed to have; instead it surfaces a clear, actionable error.
+func ensureIAPTunnelAccess(ctx context.Context, gcpclient gcpclient.Interface, opt *Options) error {
+	policy, err := gcpclient.ProjectIAM().GetPolicy(opt.ProjectID).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get IAM policy for project %s: %w", opt.ProjectID, err)
+	}
 
-	for _, item := range firewallList {
-		if err := createFirewallRuleIfNotExist(ctx, gcpclient, opt, item); err != nil {
-			return err
+	for _, binding := range policy.Bindings {
+		if binding.Role != iapTunnelResourceAccessorRole {
+			continue
+		}
+		for _, member := range binding.Members {
+			if member == fmt.Sprintf("serviceAccount:%s", opt.ServiceAccountEmail) {
+				return nil
+			}
 		}
 	}
 
-	firewall, err := getFirewallRule(ctx, gcpclient, opt, IngressAllowSSH(opt).Name)
-	if err != nil || firewall == nil {
-		return fmt.Errorf("could not get firewall rule: %w", err)
+	return fmt.Errorf("service account %q is missing role %q, which is required to tunnel to a private bastion via IAP", opt.ServiceAccountEmail, iapTunnelResourceAccessorRole)
+}
+
+// osLoginRole and osLoginAdminRole grant a user SSH access via OS Login;
+// the admin variant additionally grants sudo on the instance.
+const (
+	osLoginRole      = "roles/compute.osLogin"
+	osLoginAdminRole = "roles/compute.osAdminLogin"
+)
+
+// ensureOSLoginBinding grants opt.OSLoginUser the OS Login IAM role on the
+// project so they can authenticate to the bastion with their own Google
+// identity once enable-oslogin is set on the instance. The binding is
+// project-scoped, matching where OS Login itself is enforced, and is
+// removed again in Delete.
+func ensureOSLoginBinding(ctx context.Context, gcpclient gcpclient.Interface, opt *Options) error {
+	role := osLoginRole
+	if opt.OSLoginAdmin {
+		role = osLoginAdminRole
 	}
+	member := fmt.Sprintf("user:%s", opt.OSLoginUser)
 
-	currentCIDRs := firewall.SourceRanges
-	wantedCIDRs := opt.CIDRs
+	return addIAMPolicyBinding(ctx, gcpclient, opt.ProjectID, role, member)
+}
+
+// revokeOSLoginBinding undoes ensureOSLoginBinding; called from Delete so
+// that the user's access does not outlive the Bastion resource. The binding
+// is project-scoped, so it is left alone if another live Bastion in the same
+// namespace still grants the same user OS Login access - otherwise deleting
+// this Bastion would revoke that other, still-live Bastion's access too.
+func revokeOSLoginBinding(ctx context.Context, cli client.Client, bastion *extensionsv1alpha1.Bastion, gcpclient gcpclient.Interface, opt *Options) error {
+	shared, err := otherLiveBastionSharesOSLoginUser(ctx, cli, bastion, opt)
+	if err != nil {
+		return err
+	}
+	if shared {
+		return nil
+	}
 
-	if !reflect.DeepEqual(currentCIDRs, wantedCIDRs) {
-		return patchFirewallRule(ctx, gcpclient, opt, IngressAllowSSH(opt).Name)
+	role := osLoginRole
+	if opt.OSLoginAdmin {
+		role = osLoginAdminRole
+	}
+	member := fmt.Sprintf("user:%s", opt.OSLoginUser)
+
+	return removeIAMPolicyBinding(ctx, gcpclient, opt.ProjectID, role, member)
+}
+
+// otherLiveBastionSharesOSLoginUser reports whether another, non-deleting
+// Bastion in the same namespace (and therefore the same GCP project) carries
+// the os-login-user annotation for opt.OSLoginUser. Only that annotation is
+// compared; the other Bastion's ssh-access-mode and os-login-admin
+// annotations are not looked at.
+func otherLiveBastionSharesOSLoginUser(ctx context.Context, cli client.Client, bastion *extensionsv1alpha1.Bastion, opt *Options) (bool, error) {
+	list := &extensionsv1alpha1.BastionList{}
+	if err := cli.List(ctx, list, client.InNamespace(bastion.Namespace)); err != nil {
+		return false, fmt.Errorf("failed to list bastions to check for shared OS Login access: %w", err)
+	}
+
+	for i := range list.Items {
+		other := &list.Items[i]
+		if other.Name == bastion.Name || !other.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if other.Annotations[annotationOSLoginUser] == opt.OSLoginUser {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// addIAMPolicyBinding performs a read-modify-write of the project's IAM
+// policy to add member to role, tolerating the binding already existing.
+// The write carries the Etag read alongside the policy and is retried on a
+// conflict, so that a concurrent writer (another bastion reconciling the
+// same project, e.g. with MaxConcurrentReconciles > 1) cannot silently
+// clobber this change or have its own change clobbered.
+func addIAMPolicyBinding(ctx context.Context, gcpclient gcpclient.Interface, projectID, role, member string) error {
+	return retry.OnError(retry.DefaultBackoff, isIAMPolicyConflict, func() error {
+		policy, err := gcpclient.ProjectIAM().GetPolicy(projectID).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to get IAM policy for project %s: %w", projectID, err)
+		}
+
+		var binding *cloudresourcemanager.Binding
+		for _, b := range policy.Bindings {
+			if b.Role == role {
+				binding = b
+				break
+			}
+		}
+		if binding == nil {
+			binding = &cloudresourcemanager.Binding{Role: role}
+			policy.Bindings = append(policy.Bindings, binding)
+		}
+		for _, m := range binding.Members {
+			if m == member {
+				return nil
+			}
+		}
+		binding.Members = append(binding.Members, member)
+
+		_, err = gcpclient.ProjectIAM().SetPolicy(projectID, &cloudresourcemanager.SetIamPolicyRequest{Policy: policy}).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to set IAM policy for project %s: %w", projectID, err)
+		}
+		return nil
+	})
+}
+
+// removeIAMPolicyBinding performs a read-modify-write of the project's IAM
+// policy to remove member from role, tolerating the binding not existing.
+// See addIAMPolicyBinding for why the write is retried on an Etag conflict.
+func removeIAMPolicyBinding(ctx context.Context, gcpclient gcpclient.Interface, projectID, role, member string) error {
+	return retry.OnError(retry.DefaultBackoff, isIAMPolicyConflict, func() error {
+		policy, err := gcpclient.ProjectIAM().GetPolicy(projectID).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to get IAM policy for project %s: %w", projectID, err)
+		}
+
+		for _, b := range policy.Bindings {
+			if b.Role != role {
+				continue
+			}
+			members := make([]string, 0, len(b.Members))
+			for _, m := range b.Members {
+				if m != member {
+					members = append(members, m)
+				}
+			}
+			b.Members = members
+		}
+
+		_, err = gcpclient.ProjectIAM().SetPolicy(projectID, &cloudresourcemanager.SetIamPolicyRequest{Policy: policy}).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to set IAM policy for project %s: %w", projectID, err)
+		}
+		return nil
+	})
+}
+
+// isIAMPolicyConflict reports whether err is the 409 Conflict SetPolicy
+// returns when the policy's Etag no longer matches, i.e. someone else wrote
+// the policy between our GetPolicy and SetPolicy calls.
+func isIAMPolicyConflict(err error) bool {
+	var gerr *googleapi.Error
+	return errors.As(err, &gerr) && gerr.Code == 409
+}
+
+// ensureFirewallRules ensures that all three bastion firewall rules exist
+// and match their desired spec, patching on any divergence in source ranges,
+// priority, target tags, protocols/ports, network, direction, the disabled
+// flag, or the managed-by/bastion labels.
+func ensureFirewallRules(ctx context.Context, gcpclient gcpclient.Interface, opt *Options) error {
+	for _, desired := range []*compute.Firewall{IngressAllowSSH(opt), EgressDenyAll(opt), EgressAllowOnly(opt)} {
+		if err := reconcileFirewallRule(ctx, gcpclient, opt, desired); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// ingressSourceRanges returns the CIDRs that are allowed to reach the bastion
+// over SSH. A private, IAP-tunneled bastion only ever accepts connections
+// relayed through Google's IAP TCP-forwarding range, regardless of the CIDRs
+// the user configured on the Bastion resource.
+func ingressSourceRanges(opt *Options) []string {
+	if opt.PrivateBastion {
+		return []string{iapSourceRange}
+	}
+	return opt.CIDRs
+}
+
+// errInstanceExternallyDeleted is returned by ensureComputeInstance when an
+// instance we previously published as ready has since vanished from GCP.
+// Callers must treat this as terminal rather than retrying, since silently
+// recreating the instance would hand the user a bastion with a different
+// identity than the one they connected to.
+var errInstanceExternallyDeleted = errors.New("bastion compute instance was deleted outside of gardener")
+
 func ensureComputeInstance(ctx context.Context, logger logr.Logger, bastion *extensionsv1alpha1.Bastion, gcpclient gcpclient.Interface, opt *Options) (*compute.Instance, error) {
 	instance, err := getBastionInstance(ctx, gcpclient, opt)
 	if instance != nil || err != nil {
 		return instance, err
 	}
 
+	// If the Bastion was already reported ready once (it has a published
+	// ingress) but the instance is now gone, GCP returned a 404 behind
+	// getBastionInstance's nil-instance/nil-error contract. Recreating the
+	// instance at that point would silently swap out the bastion the user is
+	// already connected to, so surface a terminal failure instead.
+	if IngressReady(&bastion.Status.Ingress) {
+		return nil, errInstanceExternallyDeleted
+	}
+
+	if err := validateMachineType(ctx, gcpclient, opt); err != nil {
+		return nil, err
+	}
+
+	if opt.ConfidentialCompute {
+		if err := validateConfidentialComputeImage(ctx, gcpclient, opt); err != nil {
+			return nil, err
+		}
+	}
+
 	logger.Info("Creating new bastion compute instance")
 	computeInstance := computeInstanceDefine(opt, bastion.Spec.UserData)
 	_, err = gcpclient.Instances().Insert(opt.ProjectID, opt.Zone, computeInstance).Context(ctx).Do()
@@ -170,7 +411,12 @@ func ensureComputeInstance(ctx context.Context, logger logr.Logger, bastion *ext
 	return nil, fmt.Errorf("failed to get (create) bastion compute instance: %w", err)
 }
 
-func getInstanceEndpoints(instance *compute.Instance) (*bastionEndpoints, error) {
+// getInstanceEndpoints derives the bastion endpoints from the compute
+// instance. When privateBastion is true, the instance was created without an
+// AccessConfig (see networkInterfacesDefine) and the internal IP is published
+// as the public endpoint instead, since clients are expected to reach it via
+// an IAP tunnel.
+func getInstanceEndpoints(instance *compute.Instance, privateBastion bool) (*bastionEndpoints, error) {
 	if instance == nil {
 		return nil, fmt.Errorf("compute instance can't be nil")
 	}
@@ -189,16 +435,25 @@ func getInstanceEndpoints(instance *compute.Instance) (*bastionEndpoints, error)
 
 	internalIP := &networkInterfaces[0].NetworkIP
 
+	if ingress := addressToIngress(&instance.Name, internalIP); ingress != nil {
+		endpoints.private = ingress
+	}
+
+	// no AccessConfig means no external NAT IP; publish the internal IP as
+	// the "public" ingress instead (see Reconcile's IAPTunnelRequired event).
+	if privateBastion {
+		if ingress := addressToIngress(&instance.Name, internalIP); ingress != nil {
+			endpoints.public = ingress
+		}
+		return endpoints, nil
+	}
+
 	if len(networkInterfaces[0].AccessConfigs) == 0 {
 		return nil, fmt.Errorf("no access config found for network interface: %s", instance.Name)
 	}
 
 	externalIP := &networkInterfaces[0].AccessConfigs[0].NatIP
 
-	if ingress := addressToIngress(&instance.Name, internalIP); ingress != nil {
-		endpoints.private = ingress
-	}
-
 	// GCP does not automatically assign a public dns name to the instance (in contrast to e.g. AWS).
 	// As we provide an externalIP to connect to the bastion, having a public dns name would just be an alternative way to connect to the bastion.
 	// Out of this reason, we spare the effort to create a PTR record (see https://cloud.google.com/compute/docs/instances/create-ptr-record#api) just for the sake of having it.
@@ -241,7 +496,7 @@ func ensureDisk(ctx context.Context, gcpclient gcpclient.Interface, opt *Options
 	}
 
 	logger.Info("create new bastion compute instance disk")
-	disk = diskDefine(opt.Zone, opt.DiskName)
+	disk = diskDefine(opt)
 	_, err = gcpclient.Disks().Insert(opt.ProjectID, opt.Zone, disk).Context(ctx).Do()
 	if err != nil {
 		return fmt.Errorf("failed to create compute instance disk: %w", err)
@@ -256,44 +511,187 @@ func ensureDisk(ctx context.Context, gcpclient gcpclient.Interface, opt *Options
 }
 
 func computeInstanceDefine(opt *Options, userData []byte) *compute.Instance {
-	return &compute.Instance{
-		Disks:              disksDefine(opt),
-		DeletionProtection: false,
-		Description:        "Bastion Instance",
-		Name:               opt.BastionInstanceName,
-		Zone:               opt.Zone,
-		MachineType:        machineTypeDefine(opt),
-		NetworkInterfaces:  networkInterfacesDefine(opt),
-		Tags:               &compute.Tags{Items: []string{opt.BastionInstanceName}},
-		Metadata:           &compute.Metadata{Items: metadataItemsDefine(userData)},
+	instance := &compute.Instance{
+		Disks:                  disksDefine(opt),
+		DeletionProtection:     false,
+		Description:            "Bastion Instance",
+		Name:                   opt.BastionInstanceName,
+		Zone:                   opt.Zone,
+		MachineType:            machineTypeDefine(opt),
+		NetworkInterfaces:      networkInterfacesDefine(opt),
+		Tags:                   &compute.Tags{Items: []string{opt.BastionInstanceName}},
+		Labels:                 opt.Labels,
+		Metadata:               &compute.Metadata{Items: metadataItemsDefine(opt, userData)},
+		ShieldedInstanceConfig: shieldedInstanceConfigDefine(opt),
 	}
+
+	if opt.ConfidentialCompute {
+		instance.ConfidentialInstanceConfig = &compute.ConfidentialInstanceConfig{EnableConfidentialCompute: true}
+	}
+
+	return instance
 }
 
-func metadataItemsDefine(userData []byte) []*compute.MetadataItems {
-	return []*compute.MetadataItems{
-		{
-			Key:   "startup-script",
-			Value: pointer.StringPtr(string(userData)),
-		},
-		{
-			Key:   "block-project-ssh-keys",
-			Value: pointer.StringPtr("TRUE"),
-		},
+func shieldedInstanceConfigDefine(opt *Options) *compute.ShieldedInstanceConfig {
+	return &compute.ShieldedInstanceConfig{
+		EnableSecureBoot:          opt.ShieldedInstanceConfig.EnableSecureBoot,
+		EnableVtpm:                opt.ShieldedInstanceConfig.EnableVtpm,
+		EnableIntegrityMonitoring: opt.ShieldedInstanceConfig.EnableIntegrityMonitoring,
+	}
+}
+
+// sshAccessMode selects how a client's SSH public key reaches the bastion
+// instance. sshAccessModeUserData (the default) bakes the key into the
+// startup-script and disables the project-wide metadata keys entirely.
+type sshAccessMode string
+
+const (
+	// sshAccessModeUserData injects the SSH key via the startup-script
+	// user-data, as gardenctl has always done, and blocks project-wide keys.
+	sshAccessModeUserData sshAccessMode = "UserData"
+	// sshAccessModeOSLogin enables OS Login on the instance; the invoking
+	// user authenticates with their Google identity and their authorized
+	// keys are resolved from their OS Login profile instead of instance
+	// metadata.
+	sshAccessModeOSLogin sshAccessMode = "OSLogin"
+	// sshAccessModeEphemeralKey injects the caller's public key as instance
+	// "ssh-keys" metadata, annotated with an expiry so operators can see at
+	// a glance that the key is only meant to be valid for the bastion's
+	// lifetime.
+	sshAccessModeEphemeralKey sshAccessMode = "EphemeralKey"
+)
+
+func metadataItemsDefine(opt *Options, userData []byte) []*compute.MetadataItems {
+	switch opt.SSHAccessMode {
+	case sshAccessModeOSLogin:
+		return []*compute.MetadataItems{
+			{
+				Key:   "startup-script",
+				Value: pointer.StringPtr(string(userData)),
+			},
+			{
+				Key:   "enable-oslogin",
+				Value: pointer.StringPtr("TRUE"),
+			},
+		}
+	case sshAccessModeEphemeralKey:
+		return []*compute.MetadataItems{
+			{
+				Key:   "startup-script",
+				Value: pointer.StringPtr(string(userData)),
+			},
+			{
+				Key:   "block-project-ssh-keys",
+				Value: pointer.StringPtr("TRUE"),
+			},
+			{
+				Key:   "ssh-keys",
+				Value: pointer.StringPtr(ephemeralSSHKeyMetadata(opt)),
+			},
+		}
+	default:
+		return []*compute.MetadataItems{
+			{
+				Key:   "startup-script",
+				Value: pointer.StringPtr(string(userData)),
+			},
+			{
+				Key:   "block-project-ssh-keys",
+				Value: pointer.StringPtr("TRUE"),
+			},
+		}
 	}
 }
 
+// ephemeralSSHKeyMetadata formats opt.SSHPublicKey as a "ssh-keys" metadata
+// entry (`<user>:<key> google-ssh {"expireOn":"<RFC3339>"}`), the format the
+// guest agent uses to expire keys on its own rather than relying on the
+// Bastion's deletion to revoke access.
+func ephemeralSSHKeyMetadata(opt *Options) string {
+	expireOn := opt.SSHPublicKeyExpiry.UTC().Format(time.RFC3339)
+	return fmt.Sprintf("%s:%s google-ssh {\"expireOn\":%q}", opt.SSHPublicKeyUser, opt.SSHPublicKey, expireOn)
+}
+
 func machineTypeDefine(opt *Options) string {
-	return fmt.Sprintf("zones/%s/machineTypes/n1-standard-1", opt.Zone)
+	return fmt.Sprintf("zones/%s/machineTypes/%s", opt.Zone, opt.MachineType)
+}
+
+// validateMachineType checks that opt.MachineType is actually
+// offered in opt.Zone, returning a clear, user-facing error otherwise. This
+// is a preflight check to avoid failing deep inside Instances.Insert with a
+// generic 400 the user would have a hard time tracing back to their
+// providerConfig.
+func validateMachineType(ctx context.Context, gcpclient gcpclient.Interface, opt *Options) error {
+	_, err := gcpclient.MachineTypes().Get(opt.ProjectID, opt.Zone, opt.MachineType).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("machine type %q is not available in zone %q: %w", opt.MachineType, opt.Zone, err)
+	}
+	return nil
+}
+
+// validateConfidentialComputeImage checks that opt.Image declares support
+// for Confidential Computing, so that a non-supporting image does not fail
+// much later (and more confusingly) when GCP rejects the Insert call.
+func validateConfidentialComputeImage(ctx context.Context, gcpclient gcpclient.Interface, opt *Options) error {
+	image, err := lookupImage(ctx, gcpclient, opt.Image)
+	if err != nil {
+		return fmt.Errorf("failed to look up image %q: %w", opt.Image, err)
+	}
+
+	for _, family := range image.GuestOsFeatures {
+		if family.Type == "SEV_CAPABLE" {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("image %q does not support confidential compute; choose an image with the SEV_CAPABLE guest OS feature", opt.Image)
+}
+
+// lookupImage resolves opt.Image the same way imageSourceURL interprets it
+// for disk creation: a bare family name (e.g. "debian-10") or a
+// "projects/.../global/images/family/..." self-link is looked up via
+// Images.GetFromFamily, any other fully-qualified "projects/..." self-link via
+// Images.Get on its project/name. Images.Get alone cannot resolve a family
+// name, since a family has no single backing image to fetch by name.
+func lookupImage(ctx context.Context, gcpclient gcpclient.Interface, image string) (*compute.Image, error) {
+	if !strings.HasPrefix(image, "projects/") || strings.Contains(image, "/global/images/family/") {
+		return gcpclient.Images().GetFromFamily(imageProject(image), imageName(image)).Context(ctx).Do()
+	}
+	return gcpclient.Images().Get(imageProject(image), imageName(image)).Context(ctx).Do()
+}
+
+// imageProject and imageName split a bare image family (e.g. "debian-10",
+// assumed to live in the public "debian-cloud" project) from a fully
+// qualified "projects/<project>/global/images/<name-or-family>" self-link.
+func imageProject(image string) string {
+	if strings.HasPrefix(image, "projects/") {
+		parts := strings.Split(image, "/")
+		if len(parts) > 1 {
+			return parts[1]
+		}
+	}
+	return "debian-cloud"
+}
+
+func imageName(image string) string {
+	parts := strings.Split(image, "/")
+	return parts[len(parts)-1]
 }
 
 func networkInterfacesDefine(opt *Options) []*compute.NetworkInterface {
-	return []*compute.NetworkInterface{
-		{
-			Network:       opt.Network,
-			Subnetwork:    opt.Subnetwork,
-			AccessConfigs: []*compute.AccessConfig{{Name: "External NAT", Type: "ONE_TO_ONE_NAT"}},
-		},
+	networkInterface := &compute.NetworkInterface{
+		Network:    opt.Network,
+		Subnetwork: opt.Subnetwork,
+	}
+
+	// A private bastion must not get an external NAT IP: the AccessConfigs
+	// field is what causes GCP to hand out a public IP, so it is simply left
+	// unset. Ingress is restricted to Google's IAP range in ensureFirewallRules.
+	if !opt.PrivateBastion {
+		networkInterface.AccessConfigs = []*compute.AccessConfig{{Name: "External NAT", Type: "ONE_TO_ONE_NAT"}}
 	}
+
+	return []*compute.NetworkInterface{networkInterface}
 }
 
 func disksDefine(opt *Options) []*compute.AttachedDisk {
@@ -301,19 +699,30 @@ func disksDefine(opt *Options) []*compute.AttachedDisk {
 		{
 			AutoDelete: true,
 			Boot:       true,
-			DiskSizeGb: 10,
+			DiskSizeGb: opt.DiskSizeGB,
 			Source:     fmt.Sprintf("projects/%s/zones/%s/disks/%s", opt.ProjectID, opt.Zone, opt.DiskName),
 			Mode:       "READ_WRITE",
 		},
 	}
 }
 
-func diskDefine(zone string, diskName string) *compute.Disk {
+// imageSourceURL turns a bare image family name (e.g. "debian-10") into a
+// family self-link, or passes a fully-qualified image self-link through
+// unchanged.
+func imageSourceURL(image string) string {
+	if strings.HasPrefix(image, "projects/") {
+		return image
+	}
+	return fmt.Sprintf("projects/debian-cloud/global/images/family/%s", image)
+}
+
+func diskDefine(opt *Options) *compute.Disk {
 	return &compute.Disk{
 		Description: "Gardenctl Bastion disk",
-		Name:        diskName,
-		SizeGb:      10,
-		SourceImage: "projects/debian-cloud/global/images/family/debian-10",
-		Zone:        zone,
+		Name:        opt.DiskName,
+		SizeGb:      opt.DiskSizeGB,
+		SourceImage: imageSourceURL(opt.Image),
+		Type:        fmt.Sprintf("zones/%s/diskTypes/%s", opt.Zone, opt.DiskType),
+		Zone:        opt.Zone,
 	}
 }
@@ -0,0 +1,94 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetadataItemsDefine(t *testing.T) {
+	userData := []byte("#!/bin/sh\necho hi\n")
+
+	tests := []struct {
+		name        string
+		mode        sshAccessMode
+		wantKeys    []string
+		unwantedKey string
+	}{
+		{"user data mode blocks project ssh keys", sshAccessModeUserData, []string{"startup-script", "block-project-ssh-keys"}, "enable-oslogin"},
+		{"os login mode enables oslogin", sshAccessModeOSLogin, []string{"startup-script", "enable-oslogin"}, "block-project-ssh-keys"},
+		{"ephemeral key mode injects ssh-keys metadata", sshAccessModeEphemeralKey, []string{"startup-script", "block-project-ssh-keys", "ssh-keys"}, "enable-oslogin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opt := &Options{
+				SSHAccessMode:      tt.mode,
+				SSHPublicKey:       "ssh-rsa AAAA...",
+				SSHPublicKeyUser:   "core",
+				SSHPublicKeyExpiry: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			}
+
+			items := metadataItemsDefine(opt, userData)
+
+			keys := make(map[string]bool, len(items))
+			for _, item := range items {
+				keys[item.Key] = true
+			}
+			for _, want := range tt.wantKeys {
+				if !keys[want] {
+					t.Errorf("metadataItemsDefine(%s) missing key %q, got %v", tt.mode, want, keys)
+				}
+			}
+			if keys[tt.unwantedKey] {
+				t.Errorf("metadataItemsDefine(%s) unexpectedly set key %q", tt.mode, tt.unwantedKey)
+			}
+		})
+	}
+}
+
+func TestEphemeralSSHKeyMetadata(t *testing.T) {
+	opt := &Options{
+		SSHPublicKeyUser:   "core",
+		SSHPublicKey:       "ssh-rsa AAAA...",
+		SSHPublicKeyExpiry: time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+	}
+
+	want := `core:ssh-rsa AAAA... google-ssh {"expireOn":"2026-01-02T15:04:05Z"}`
+	if got := ephemeralSSHKeyMetadata(opt); got != want {
+		t.Errorf("ephemeralSSHKeyMetadata() = %q, want %q", got, want)
+	}
+}
+
+func TestIngressSourceRanges(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  *Options
+		want []string
+	}{
+		{"private bastion is restricted to the IAP range", &Options{PrivateBastion: true, CIDRs: []string{"1.2.3.0/24"}}, []string{iapSourceRange}},
+		{"public bastion uses the configured CIDRs", &Options{PrivateBastion: false, CIDRs: []string{"1.2.3.0/24"}}, []string{"1.2.3.0/24"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ingressSourceRanges(tt.opt)
+			if len(got) != len(tt.want) || got[0] != tt.want[0] {
+				t.Errorf("ingressSourceRanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
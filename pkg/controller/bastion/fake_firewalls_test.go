@@ -0,0 +1,98 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"context"
+
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// fakeFirewalls backs Get/Insert/Patch/Delete with an in-memory map keyed by
+// firewall rule name, so reconcileFirewallRule's read-then-create-or-patch
+// logic can be exercised without a real GCP network.
+type fakeFirewalls struct {
+	rules map[string]*compute.Firewall
+
+	insertCalls int
+	patchCalls  int
+	deleteCalls int
+}
+
+func newFakeFirewalls(rules ...*compute.Firewall) *fakeFirewalls {
+	f := &fakeFirewalls{rules: map[string]*compute.Firewall{}}
+	for _, r := range rules {
+		f.rules[r.Name] = r
+	}
+	return f
+}
+
+func (f *fakeFirewalls) Get(projectID, name string) *fakeFirewallGetCall {
+	return &fakeFirewallGetCall{firewalls: f, name: name}
+}
+
+type fakeFirewallGetCall struct {
+	firewalls *fakeFirewalls
+	name      string
+}
+
+func (c *fakeFirewallGetCall) Context(ctx context.Context) *fakeFirewallGetCall { return c }
+
+func (c *fakeFirewallGetCall) Do(...googleapi.CallOption) (*compute.Firewall, error) {
+	rule, ok := c.firewalls.rules[c.name]
+	if !ok {
+		return nil, &googleapi.Error{Code: 404, Message: "firewall rule not found"}
+	}
+	return rule, nil
+}
+
+func (f *fakeFirewalls) Insert(projectID string, rule *compute.Firewall) *fakeFirewallWriteCall {
+	return &fakeFirewallWriteCall{firewalls: f, rule: rule, op: "insert"}
+}
+
+func (f *fakeFirewalls) Patch(projectID, name string, rule *compute.Firewall) *fakeFirewallWriteCall {
+	return &fakeFirewallWriteCall{firewalls: f, rule: rule, op: "patch"}
+}
+
+func (f *fakeFirewalls) Delete(projectID, name string) *fakeFirewallWriteCall {
+	return &fakeFirewallWriteCall{firewalls: f, rule: &compute.Firewall{Name: name}, op: "delete"}
+}
+
+type fakeFirewallWriteCall struct {
+	firewalls *fakeFirewalls
+	rule      *compute.Firewall
+	op        string
+}
+
+func (c *fakeFirewallWriteCall) Context(ctx context.Context) *fakeFirewallWriteCall { return c }
+
+func (c *fakeFirewallWriteCall) Do(...googleapi.CallOption) (*compute.Operation, error) {
+	switch c.op {
+	case "insert":
+		c.firewalls.insertCalls++
+		c.firewalls.rules[c.rule.Name] = c.rule
+	case "patch":
+		c.firewalls.patchCalls++
+		c.firewalls.rules[c.rule.Name] = c.rule
+	case "delete":
+		c.firewalls.deleteCalls++
+		if _, ok := c.firewalls.rules[c.rule.Name]; !ok {
+			return nil, &googleapi.Error{Code: 404, Message: "firewall rule not found"}
+		}
+		delete(c.firewalls.rules, c.rule.Name)
+	}
+	return &compute.Operation{}, nil
+}
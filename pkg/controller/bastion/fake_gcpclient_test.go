@@ -0,0 +1,84 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"context"
+
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/googleapi"
+)
+
+// fakeGCPClient is a minimal, hand-rolled stand-in for gcpclient.Interface
+// covering only the calls the bastion controller makes, so that its control
+// flow can be exercised without a real GCP project.
+type fakeGCPClient struct {
+	projectIAM *fakeProjectIAM
+	firewalls  *fakeFirewalls
+}
+
+func (f *fakeGCPClient) ProjectIAM() *fakeProjectIAM { return f.projectIAM }
+func (f *fakeGCPClient) Firewalls() *fakeFirewalls   { return f.firewalls }
+
+// fakeProjectIAM backs GetPolicy/SetPolicy with an in-memory policy. Setting
+// conflictsBeforeSuccess > 0 makes the next that many SetPolicy calls fail
+// with a 409, to exercise addIAMPolicyBinding/removeIAMPolicyBinding's retry.
+type fakeProjectIAM struct {
+	policy                 *cloudresourcemanager.Policy
+	conflictsBeforeSuccess int
+	setPolicyCalls         int
+}
+
+func (f *fakeProjectIAM) GetPolicy(projectID string) *fakeGetPolicyCall {
+	return &fakeGetPolicyCall{iam: f}
+}
+
+type fakeGetPolicyCall struct{ iam *fakeProjectIAM }
+
+func (c *fakeGetPolicyCall) Context(ctx context.Context) *fakeGetPolicyCall { return c }
+
+func (c *fakeGetPolicyCall) Do(...googleapi.CallOption) (*cloudresourcemanager.Policy, error) {
+	// return a copy, so that a caller mutating the returned Bindings can't
+	// retroactively change what a concurrent reader already observed
+	policy := &cloudresourcemanager.Policy{Etag: c.iam.policy.Etag}
+	for _, b := range c.iam.policy.Bindings {
+		policy.Bindings = append(policy.Bindings, &cloudresourcemanager.Binding{
+			Role:    b.Role,
+			Members: append([]string(nil), b.Members...),
+		})
+	}
+	return policy, nil
+}
+
+func (f *fakeProjectIAM) SetPolicy(projectID string, req *cloudresourcemanager.SetIamPolicyRequest) *fakeSetPolicyCall {
+	return &fakeSetPolicyCall{iam: f, req: req}
+}
+
+type fakeSetPolicyCall struct {
+	iam *fakeProjectIAM
+	req *cloudresourcemanager.SetIamPolicyRequest
+}
+
+func (c *fakeSetPolicyCall) Context(ctx context.Context) *fakeSetPolicyCall { return c }
+
+func (c *fakeSetPolicyCall) Do(...googleapi.CallOption) (*cloudresourcemanager.Policy, error) {
+	c.iam.setPolicyCalls++
+	if c.iam.conflictsBeforeSuccess > 0 {
+		c.iam.conflictsBeforeSuccess--
+		return nil, &googleapi.Error{Code: 409, Message: "etag mismatch"}
+	}
+	c.iam.policy = c.req.Policy
+	return c.iam.policy, nil
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"context"
+	"fmt"
+
+	gcpclient "github.com/gardener/gardener-extension-provider-gcp/pkg/internal/client"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/googleapi"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	// firewallManagedByLabelKey and firewallManagedByLabelValue mark a
+	// firewall rule as owned by this extension, so that reconcile can tell
+	// its own rules apart from ones authored by other tools or operators.
+	firewallManagedByLabelKey   = "managed-by"
+	firewallManagedByLabelValue = "gardener-extension-provider-gcp"
+	// firewallBastionLabelKey records which Bastion a rule belongs to.
+	firewallBastionLabelKey = "bastion"
+)
+
+// firewallLabels returns the labels every firewall rule owned by this
+// extension must carry. They are applied on creation and re-asserted on
+// every reconcile, so that out-of-band edits by operators are reverted.
+func firewallLabels(opt *Options) map[string]string {
+	return map[string]string{
+		firewallManagedByLabelKey: firewallManagedByLabelValue,
+		firewallBastionLabelKey:   opt.BastionInstanceName,
+	}
+}
+
+// IngressAllowSSH builds the desired firewall rule allowing SSH ingress to
+// the bastion instance from the configured CIDRs (or, for a private bastion,
+// from Google's IAP range, see ingressSourceRanges).
+func IngressAllowSSH(opt *Options) *compute.Firewall {
+	return &compute.Firewall{
+		Name:         fmt.Sprintf("%s-allow-ssh", opt.BastionInstanceName),
+		Network:      opt.Network,
+		Direction:    "INGRESS",
+		SourceRanges: ingressSourceRanges(opt),
+		TargetTags:   []string{opt.BastionInstanceName},
+		Allowed:      []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22"}}},
+		Priority:     1000,
+		Disabled:     false,
+		Description:  "SSH access for bastion host",
+		Labels:       firewallLabels(opt),
+	}
+}
+
+// EgressDenyAll builds the desired firewall rule that denies all egress
+// traffic from the bastion instance by default; EgressAllowOnly punches the
+// narrow hole this rule otherwise closes.
+func EgressDenyAll(opt *Options) *compute.Firewall {
+	return &compute.Firewall{
+		Name:              fmt.Sprintf("%s-deny-all", opt.BastionInstanceName),
+		Network:           opt.Network,
+		Direction:         "EGRESS",
+		DestinationRanges: []string{"0.0.0.0/0"},
+		TargetTags:        []string{opt.BastionInstanceName},
+		Denied:            []*compute.FirewallDenied{{IPProtocol: "all"}},
+		Priority:          1000,
+		Disabled:          false,
+		Description:       "Denies all egress traffic from the bastion host",
+		Labels:            firewallLabels(opt),
+	}
+}
+
+// EgressAllowOnly builds the desired firewall rule allowing the bastion
+// instance to reach the worker nodes over SSH; this is the one hole in the
+// egress-deny-all rule.
+func EgressAllowOnly(opt *Options) *compute.Firewall {
+	return &compute.Firewall{
+		Name:              fmt.Sprintf("%s-allow-only", opt.BastionInstanceName),
+		Network:           opt.Network,
+		Direction:         "EGRESS",
+		DestinationRanges: opt.WorkerCIDRs,
+		TargetTags:        []string{opt.BastionInstanceName},
+		Allowed:           []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22"}}},
+		Priority:          900,
+		Disabled:          false,
+		Description:       "Allows the bastion host to reach the worker nodes via SSH",
+		Labels:            firewallLabels(opt),
+	}
+}
+
+// getFirewallRule looks up a firewall rule by name, translating a 404 into
+// (nil, nil) so callers can use the same "not found yet" idiom as
+// getBastionInstance/getDisk.
+func getFirewallRule(ctx context.Context, gcpclient gcpclient.Interface, opt *Options, name string) (*compute.Firewall, error) {
+	firewall, err := gcpclient.Firewalls().Get(opt.ProjectID, name).Context(ctx).Do()
+	if err != nil {
+		if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return firewall, nil
+}
+
+// createFirewallRuleIfNotExist creates the desired firewall rule if no rule
+// with that name exists yet.
+func createFirewallRuleIfNotExist(ctx context.Context, gcpclient gcpclient.Interface, opt *Options, desired *compute.Firewall) error {
+	existing, err := getFirewallRule(ctx, gcpclient, opt, desired.Name)
+	if err != nil {
+		return fmt.Errorf("could not get firewall rule %q: %w", desired.Name, err)
+	}
+	if existing != nil {
+		return nil
+	}
+
+	_, err = gcpclient.Firewalls().Insert(opt.ProjectID, desired).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to create firewall rule %q: %w", desired.Name, err)
+	}
+	return nil
+}
+
+// firewallDrifted reports whether current diverges from desired on any
+// field gardener is responsible for managing: source/destination ranges,
+// allowed/denied protocols and ports, target tags, priority, network,
+// direction, the disabled flag, and the managed-by/bastion labels.
+func firewallDrifted(current, desired *compute.Firewall) bool {
+	return !stringSlicesEqual(current.SourceRanges, desired.SourceRanges) ||
+		!stringSlicesEqual(current.DestinationRanges, desired.DestinationRanges) ||
+		!stringSlicesEqual(current.TargetTags, desired.TargetTags) ||
+		!allowedEqual(current.Allowed, desired.Allowed) ||
+		!deniedEqual(current.Denied, desired.Denied) ||
+		current.Priority != desired.Priority ||
+		current.Network != desired.Network ||
+		current.Direction != desired.Direction ||
+		current.Disabled != desired.Disabled ||
+		!labelsContain(current.Labels, desired.Labels)
+}
+
+// reconcileFirewallRule ensures a single firewall rule exists and matches
+// its desired spec, patching it on any divergence. Rules that were created
+// by something other than this extension (missing the managed-by label) are
+// left untouched, so that operators can author their own firewall rules on
+// the same network without gardener reverting them.
+func reconcileFirewallRule(ctx context.Context, gcpclient gcpclient.Interface, opt *Options, desired *compute.Firewall) error {
+	if err := createFirewallRuleIfNotExist(ctx, gcpclient, opt, desired); err != nil {
+		return err
+	}
+
+	current, err := getFirewallRule(ctx, gcpclient, opt, desired.Name)
+	if err != nil || current == nil {
+		return fmt.Errorf("could not get firewall rule %q: %w", desired.Name, err)
+	}
+
+	if current.Labels[firewallManagedByLabelKey] != firewallManagedByLabelValue {
+		return nil
+	}
+
+	if !firewallDrifted(current, desired) {
+		return nil
+	}
+
+	return patchFirewallRule(ctx, gcpclient, opt, desired)
+}
+
+// patchFirewallRule patches an existing firewall rule to match desired.
+func patchFirewallRule(ctx context.Context, gcpclient gcpclient.Interface, opt *Options, desired *compute.Firewall) error {
+	_, err := gcpclient.Firewalls().Patch(opt.ProjectID, desired.Name, desired).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to patch firewall rule %q: %w", desired.Name, err)
+	}
+	return nil
+}
+
+// deleteFirewallRules removes all three bastion firewall rules, tolerating
+// rules that no longer exist. Like reconcileFirewallRule, it only ever
+// touches rules carrying the managed-by label, so a rule that happens to
+// share a name with one of ours but was authored by another tool is left
+// alone.
+func deleteFirewallRules(ctx context.Context, gcpclient gcpclient.Interface, opt *Options) error {
+	for _, desired := range []*compute.Firewall{IngressAllowSSH(opt), EgressDenyAll(opt), EgressAllowOnly(opt)} {
+		current, err := getFirewallRule(ctx, gcpclient, opt, desired.Name)
+		if err != nil {
+			return fmt.Errorf("could not get firewall rule %q: %w", desired.Name, err)
+		}
+		if current == nil || current.Labels[firewallManagedByLabelKey] != firewallManagedByLabelValue {
+			continue
+		}
+
+		_, err = gcpclient.Firewalls().Delete(opt.ProjectID, desired.Name).Context(ctx).Do()
+		if err != nil {
+			if gerr, ok := err.(*googleapi.Error); ok && gerr.Code == 404 {
+				continue
+			}
+			return fmt.Errorf("failed to delete firewall rule %q: %w", desired.Name, err)
+		}
+	}
+	return nil
+}
+
+// stringSlicesEqual compares two slices as sets: GCP does not guarantee the
+// order it returns SourceRanges, TargetTags or port lists in, so comparing
+// index-by-index would flag unrelated re-orderings as drift.
+func stringSlicesEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	aSet := sets.NewString(a...)
+	return aSet.Equal(sets.NewString(b...))
+}
+
+func allowedEqual(a, b []*compute.FirewallAllowed) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].IPProtocol != b[i].IPProtocol || !stringSlicesEqual(a[i].Ports, b[i].Ports) {
+			return false
+		}
+	}
+	return true
+}
+
+func deniedEqual(a, b []*compute.FirewallDenied) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].IPProtocol != b[i].IPProtocol || !stringSlicesEqual(a[i].Ports, b[i].Ports) {
+			return false
+		}
+	}
+	return true
+}
+
+// labelsContain reports whether actual contains every key/value pair in
+// wanted; this lets an operator add their own extra labels to a rule
+// without gardener fighting them over it, while still enforcing its own.
+func labelsContain(actual, wanted map[string]string) bool {
+	for k, v := range wanted {
+		if actual[k] != v {
+			return false
+		}
+	}
+	return true
+}
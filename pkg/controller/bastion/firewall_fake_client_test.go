@@ -0,0 +1,115 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func newFakeFirewallClient(rules ...*compute.Firewall) *fakeGCPClient {
+	return &fakeGCPClient{firewalls: newFakeFirewalls(rules...)}
+}
+
+func TestCreateFirewallRuleIfNotExist(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{BastionInstanceName: "shoot--foo-bastion-bar", Network: "my-network"}
+	desired := IngressAllowSSH(opt)
+
+	cli := newFakeFirewallClient()
+	if err := createFirewallRuleIfNotExist(ctx, cli, opt, desired); err != nil {
+		t.Fatalf("createFirewallRuleIfNotExist returned error: %v", err)
+	}
+	if cli.firewalls.insertCalls != 1 {
+		t.Fatalf("expected a single Insert call, got %d", cli.firewalls.insertCalls)
+	}
+
+	// calling again must be a no-op, since the rule now exists
+	if err := createFirewallRuleIfNotExist(ctx, cli, opt, desired); err != nil {
+		t.Fatalf("createFirewallRuleIfNotExist returned error on second call: %v", err)
+	}
+	if cli.firewalls.insertCalls != 1 {
+		t.Fatalf("expected Insert to still have been called only once, got %d", cli.firewalls.insertCalls)
+	}
+}
+
+func TestReconcileFirewallRulePatchesOnDrift(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{BastionInstanceName: "shoot--foo-bastion-bar", Network: "my-network"}
+	desired := IngressAllowSSH(opt)
+
+	current := IngressAllowSSH(opt)
+	current.SourceRanges = []string{"10.0.0.0/8"} // drifted from desired
+
+	cli := newFakeFirewallClient(current)
+	if err := reconcileFirewallRule(ctx, cli, opt, desired); err != nil {
+		t.Fatalf("reconcileFirewallRule returned error: %v", err)
+	}
+	if cli.firewalls.patchCalls != 1 {
+		t.Fatalf("expected a single Patch call, got %d", cli.firewalls.patchCalls)
+	}
+	if !stringSlicesEqual(cli.firewalls.rules[desired.Name].SourceRanges, desired.SourceRanges) {
+		t.Fatalf("expected source ranges to converge on desired, got %v", cli.firewalls.rules[desired.Name].SourceRanges)
+	}
+}
+
+func TestReconcileFirewallRuleLeavesUnmanagedRuleAlone(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{BastionInstanceName: "shoot--foo-bastion-bar", Network: "my-network"}
+	desired := IngressAllowSSH(opt)
+
+	current := IngressAllowSSH(opt)
+	current.SourceRanges = []string{"10.0.0.0/8"}
+	current.Labels = map[string]string{} // not managed by this extension
+
+	cli := newFakeFirewallClient(current)
+	if err := reconcileFirewallRule(ctx, cli, opt, desired); err != nil {
+		t.Fatalf("reconcileFirewallRule returned error: %v", err)
+	}
+	if cli.firewalls.patchCalls != 0 {
+		t.Fatalf("expected an unmanaged rule to be left alone, got %d Patch calls", cli.firewalls.patchCalls)
+	}
+}
+
+func TestReconcileFirewallRuleNoOpWhenUpToDate(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{BastionInstanceName: "shoot--foo-bastion-bar", Network: "my-network"}
+	desired := IngressAllowSSH(opt)
+
+	cli := newFakeFirewallClient(IngressAllowSSH(opt))
+	if err := reconcileFirewallRule(ctx, cli, opt, desired); err != nil {
+		t.Fatalf("reconcileFirewallRule returned error: %v", err)
+	}
+	if cli.firewalls.patchCalls != 0 {
+		t.Fatalf("expected no Patch call when the rule already matches desired, got %d", cli.firewalls.patchCalls)
+	}
+}
+
+func TestPatchFirewallRule(t *testing.T) {
+	ctx := context.Background()
+	opt := &Options{BastionInstanceName: "shoot--foo-bastion-bar", Network: "my-network"}
+	desired := IngressAllowSSH(opt)
+	desired.Disabled = true
+
+	cli := newFakeFirewallClient(IngressAllowSSH(opt))
+	if err := patchFirewallRule(ctx, cli, opt, desired); err != nil {
+		t.Fatalf("patchFirewallRule returned error: %v", err)
+	}
+	if !cli.firewalls.rules[desired.Name].Disabled {
+		t.Fatalf("expected the firewall rule to be patched to disabled")
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"testing"
+
+	"google.golang.org/api/compute/v1"
+)
+
+func baseFirewall() *compute.Firewall {
+	return &compute.Firewall{
+		Name:              "bastion-allow-ssh",
+		Network:           "projects/p/global/networks/n",
+		Direction:         "INGRESS",
+		SourceRanges:      []string{"10.0.0.0/24", "10.0.1.0/24"},
+		DestinationRanges: []string{"10.0.2.0/24", "10.0.3.0/24"},
+		TargetTags:        []string{"bastion", "bastion-ssh"},
+		Allowed:           []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22", "2222"}}},
+		Denied:            []*compute.FirewallDenied{{IPProtocol: "all"}},
+		Priority:          1000,
+		Disabled:          false,
+		Labels:            map[string]string{firewallManagedByLabelKey: firewallManagedByLabelValue, firewallBastionLabelKey: "bastion"},
+	}
+}
+
+func TestFirewallDrifted(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(*compute.Firewall)
+		drifted bool
+	}{
+		{"identical", func(*compute.Firewall) {}, false},
+		{"reordered source ranges are not drift", func(f *compute.Firewall) {
+			f.SourceRanges = []string{"10.0.1.0/24", "10.0.0.0/24"}
+		}, false},
+		{"reordered destination ranges are not drift", func(f *compute.Firewall) {
+			f.DestinationRanges = []string{"10.0.3.0/24", "10.0.2.0/24"}
+		}, false},
+		{"reordered target tags are not drift", func(f *compute.Firewall) {
+			f.TargetTags = []string{"bastion-ssh", "bastion"}
+		}, false},
+		{"changed source ranges is drift", func(f *compute.Firewall) {
+			f.SourceRanges = []string{"10.0.0.0/24"}
+		}, true},
+		{"changed allowed ports is drift", func(f *compute.Firewall) {
+			f.Allowed = []*compute.FirewallAllowed{{IPProtocol: "tcp", Ports: []string{"22"}}}
+		}, true},
+		{"changed denied protocol is drift", func(f *compute.Firewall) {
+			f.Denied = []*compute.FirewallDenied{{IPProtocol: "tcp"}}
+		}, true},
+		{"changed priority is drift", func(f *compute.Firewall) {
+			f.Priority = 900
+		}, true},
+		{"changed network is drift", func(f *compute.Firewall) {
+			f.Network = "projects/p/global/networks/other"
+		}, true},
+		{"changed direction is drift", func(f *compute.Firewall) {
+			f.Direction = "EGRESS"
+		}, true},
+		{"changed disabled flag is drift", func(f *compute.Firewall) {
+			f.Disabled = true
+		}, true},
+		{"missing managed-by label is drift", func(f *compute.Firewall) {
+			delete(f.Labels, firewallManagedByLabelKey)
+		}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current := baseFirewall()
+			desired := baseFirewall()
+			tt.mutate(current)
+
+			if got := firewallDrifted(current, desired); got != tt.drifted {
+				t.Errorf("firewallDrifted() = %v, want %v", got, tt.drifted)
+			}
+		})
+	}
+}
+
+func TestStringSlicesEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []string
+		want bool
+	}{
+		{"equal order", []string{"a", "b"}, []string{"a", "b"}, true},
+		{"equal out of order", []string{"a", "b"}, []string{"b", "a"}, true},
+		{"different length", []string{"a"}, []string{"a", "b"}, false},
+		{"different elements", []string{"a", "b"}, []string{"a", "c"}, false},
+		{"both empty", nil, []string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stringSlicesEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("stringSlicesEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLabelsContain(t *testing.T) {
+	actual := map[string]string{"managed-by": "gardener-extension-provider-gcp", "extra": "operator-added"}
+
+	if !labelsContain(actual, map[string]string{"managed-by": "gardener-extension-provider-gcp"}) {
+		t.Error("expected actual to contain the wanted managed-by label")
+	}
+	if labelsContain(actual, map[string]string{"managed-by": "something-else"}) {
+		t.Error("expected mismatched label value to not be contained")
+	}
+}
@@ -0,0 +1,256 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gardener/gardener-extension-provider-gcp/pkg/apis/gcp"
+	gcpv1alpha1 "github.com/gardener/gardener-extension-provider-gcp/pkg/apis/gcp/v1alpha1"
+	gcpvalidation "github.com/gardener/gardener-extension-provider-gcp/pkg/apis/gcp/validation"
+	"github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// Options bundles everything the bastion actuator needs to reconcile,
+// delete, or TTL-check a single Bastion's GCP resources.
+type Options struct {
+	ProjectID           string
+	Zone                string
+	Network             string
+	Subnetwork          string
+	BastionInstanceName string
+	DiskName            string
+	CIDRs               []string
+	WorkerCIDRs         []string
+	ServiceAccountEmail string
+
+	PrivateBastion bool
+	SSHAccessMode  sshAccessMode
+
+	OSLoginUser  string
+	OSLoginAdmin bool
+
+	SSHPublicKey       string
+	SSHPublicKeyUser   string
+	SSHPublicKeyExpiry time.Time
+
+	MachineType            string
+	Image                  string
+	DiskSizeGB             int64
+	DiskType               string
+	Labels                 map[string]string
+	ShieldedInstanceConfig ShieldedInstanceConfigOptions
+	ConfidentialCompute    bool
+}
+
+// ShieldedInstanceConfigOptions mirrors gcpv1alpha1.ShieldedInstanceConfig
+// with its defaulting already applied, i.e. no nil pointers left.
+type ShieldedInstanceConfigOptions struct {
+	EnableSecureBoot          bool
+	EnableVtpm                bool
+	EnableIntegrityMonitoring bool
+}
+
+// These annotations carry per-bastion access settings that have no home in
+// extensionsv1alpha1.Bastion's provider-agnostic spec. gardenctl sets them
+// on the Bastion resource it creates.
+const (
+	annotationPrivateBastion    = "bastion.gcp.provider.extensions.gardener.cloud/private-bastion"
+	annotationSSHAccessMode     = "bastion.gcp.provider.extensions.gardener.cloud/ssh-access-mode"
+	annotationOSLoginUser       = "bastion.gcp.provider.extensions.gardener.cloud/os-login-user"
+	annotationOSLoginAdmin      = "bastion.gcp.provider.extensions.gardener.cloud/os-login-admin"
+	annotationSSHPublicKey      = "bastion.gcp.provider.extensions.gardener.cloud/ssh-public-key"
+	annotationSSHPublicKeyUser  = "bastion.gcp.provider.extensions.gardener.cloud/ssh-public-key-user"
+	annotationSSHPublicKeyUntil = "bastion.gcp.provider.extensions.gardener.cloud/ssh-public-key-expiry"
+)
+
+// DetermineOptions assembles the Options for a single Bastion from the
+// Bastion and its Shoot cluster.
+func DetermineOptions(bastion *extensionsv1alpha1.Bastion, cluster *controller.Cluster, projectID string) (*Options, error) {
+	config, err := bastionConfigFromProviderConfig(bastion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode bastion providerConfig: %w", err)
+	}
+	gcpv1alpha1.SetDefaults_BastionConfig(config)
+	if err := validateBastionConfig(config); err != nil {
+		return nil, err
+	}
+
+	sshAccessMode, err := determineSSHAccessMode(bastion)
+	if err != nil {
+		return nil, err
+	}
+
+	instanceName := fmt.Sprintf("%s-bastion-%s", cluster.ObjectMeta.Name, bastion.Name)
+
+	opt := &Options{
+		ProjectID:           projectID,
+		Zone:                getZoneFromProviderStatus(bastion),
+		Network:             networkName(cluster),
+		Subnetwork:          subnetworkName(cluster),
+		BastionInstanceName: instanceName,
+		DiskName:            instanceName + "-disk",
+		CIDRs:               ingressCIDRs(bastion),
+		WorkerCIDRs:         workerCIDRs(cluster),
+
+		PrivateBastion: bastion.Annotations[annotationPrivateBastion] == "true",
+		SSHAccessMode:  sshAccessMode,
+
+		OSLoginUser:  bastion.Annotations[annotationOSLoginUser],
+		OSLoginAdmin: bastion.Annotations[annotationOSLoginAdmin] == "true",
+
+		SSHPublicKey:     bastion.Annotations[annotationSSHPublicKey],
+		SSHPublicKeyUser: bastion.Annotations[annotationSSHPublicKeyUser],
+
+		MachineType: *config.MachineType,
+		Image:       *config.Image,
+		DiskSizeGB:  int64(*config.DiskSizeGB),
+		DiskType:    *config.DiskType,
+		Labels:      config.Labels,
+		ShieldedInstanceConfig: ShieldedInstanceConfigOptions{
+			EnableSecureBoot:          *config.ShieldedInstanceConfig.EnableSecureBoot,
+			EnableVtpm:                *config.ShieldedInstanceConfig.EnableVtpm,
+			EnableIntegrityMonitoring: *config.ShieldedInstanceConfig.EnableIntegrityMonitoring,
+		},
+		ConfidentialCompute: *config.ConfidentialCompute,
+	}
+
+	if sshAccessMode == sshAccessModeEphemeralKey {
+		expiry, err := time.Parse(time.RFC3339, bastion.Annotations[annotationSSHPublicKeyUntil])
+		if err != nil {
+			return nil, fmt.Errorf("invalid or missing %q annotation for ephemeral SSH key access: %w", annotationSSHPublicKeyUntil, err)
+		}
+		opt.SSHPublicKeyExpiry = expiry
+	}
+
+	return opt, nil
+}
+
+// determineSSHAccessMode reads the ssh-access-mode annotation, defaulting to
+// sshAccessModeUserData when unset.
+func determineSSHAccessMode(bastion *extensionsv1alpha1.Bastion) (sshAccessMode, error) {
+	raw, ok := bastion.Annotations[annotationSSHAccessMode]
+	if !ok || raw == "" {
+		return sshAccessModeUserData, nil
+	}
+
+	switch mode := sshAccessMode(raw); mode {
+	case sshAccessModeUserData, sshAccessModeOSLogin, sshAccessModeEphemeralKey:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown value %q for annotation %q", raw, annotationSSHAccessMode)
+	}
+}
+
+// ingressCIDRs flattens Bastion.Spec.Ingress into the plain CIDR list the
+// firewall rules are built from.
+func ingressCIDRs(bastion *extensionsv1alpha1.Bastion) []string {
+	cidrs := make([]string, 0, len(bastion.Spec.Ingress))
+	for _, ingress := range bastion.Spec.Ingress {
+		cidrs = append(cidrs, ingress.IPBlock.CIDR)
+	}
+	return cidrs
+}
+
+// workerCIDRs returns the node CIDR of the Shoot cluster, which the
+// egress-allow-only firewall rule is scoped to.
+func workerCIDRs(cluster *controller.Cluster) []string {
+	if cluster.Shoot == nil || cluster.Shoot.Spec.Networking.Nodes == nil {
+		return nil
+	}
+	return []string{*cluster.Shoot.Spec.Networking.Nodes}
+}
+
+// networkName and subnetworkName derive the GCP VPC/subnetwork the bastion
+// instance is attached to from the Shoot's name, matching the naming scheme
+// the infrastructure controller uses when creating them.
+func networkName(cluster *controller.Cluster) string {
+	if cluster.Shoot == nil {
+		return ""
+	}
+	return cluster.ObjectMeta.Name
+}
+
+func subnetworkName(cluster *controller.Cluster) string {
+	if cluster.Shoot == nil {
+		return ""
+	}
+	return cluster.ObjectMeta.Name + "-nodes"
+}
+
+// bastionConfigFromProviderConfig decodes bastion.Spec.ProviderConfig into a
+// BastionConfig, returning an empty (all-defaults) config if none was given.
+func bastionConfigFromProviderConfig(bastion *extensionsv1alpha1.Bastion) (*gcpv1alpha1.BastionConfig, error) {
+	config := &gcpv1alpha1.BastionConfig{}
+	if bastion.Spec.ProviderConfig == nil || len(bastion.Spec.ProviderConfig.Raw) == 0 {
+		return config, nil
+	}
+
+	if err := json.Unmarshal(bastion.Spec.ProviderConfig.Raw, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validateBastionConfig runs gcpvalidation.ValidateBastionConfig against the
+// already-defaulted config, so that e.g. confidentialCompute is checked
+// against the machine type that will actually be used, not just one the
+// user happened to set explicitly.
+func validateBastionConfig(config *gcpv1alpha1.BastionConfig) error {
+	internal := &gcp.BastionConfig{
+		MachineType:         config.MachineType,
+		Image:               config.Image,
+		DiskSizeGB:          config.DiskSizeGB,
+		DiskType:            config.DiskType,
+		Labels:              config.Labels,
+		ConfidentialCompute: config.ConfidentialCompute,
+	}
+	if config.ShieldedInstanceConfig != nil {
+		internal.ShieldedInstanceConfig = &gcp.ShieldedInstanceConfig{
+			EnableSecureBoot:          config.ShieldedInstanceConfig.EnableSecureBoot,
+			EnableVtpm:                config.ShieldedInstanceConfig.EnableVtpm,
+			EnableIntegrityMonitoring: config.ShieldedInstanceConfig.EnableIntegrityMonitoring,
+		}
+	}
+
+	if allErrs := gcpvalidation.ValidateBastionConfig(internal, field.NewPath("providerConfig")); len(allErrs) > 0 {
+		return allErrs.ToAggregate()
+	}
+	return nil
+}
+
+// providerStatus mirrors the shape marshalProviderStatus writes to
+// status.providerStatus.
+type providerStatus struct {
+	Zone string `json:"zone"`
+}
+
+// getZoneFromProviderStatus extracts the zone from a Bastion's
+// status.providerStatus, or returns the empty string if none has been
+// recorded yet.
+func getZoneFromProviderStatus(bastion *extensionsv1alpha1.Bastion) string {
+	if bastion.Status.ProviderStatus == nil || len(bastion.Status.ProviderStatus.Raw) == 0 {
+		return ""
+	}
+	ps := &providerStatus{}
+	if err := json.Unmarshal(bastion.Status.ProviderStatus.Raw, ps); err != nil {
+		return ""
+	}
+	return ps.Zone
+}
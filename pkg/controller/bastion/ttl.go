@@ -0,0 +1,315 @@
+// Copyright (c) 2021 SAP SE or an SAP affiliate company. All rights reserved. This file is licensed under the Apache Software License, v. 2 except as noted otherwise in the LICENSE file
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bastion
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	gcpclient "github.com/gardener/gardener-extension-provider-gcp/pkg/internal/client"
+	"github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/spf13/pflag"
+	"google.golang.org/api/compute/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// defaultTTLCheckPeriod is used when --bastion-ttl-check-period is not set.
+const defaultTTLCheckPeriod = 30 * time.Second
+
+// TTLCheckPeriodFlag is the name of the command line flag that configures
+// how often the TTL reconciler polls bastion instances.
+const TTLCheckPeriodFlag = "ttl-check-period"
+
+// TTLOptions are command line options that can be set for the TTL
+// enforcement loop of the bastion controller. It is meant to be combined
+// with ControllerOptions under the "bastion-" flag prefix, so that the flag
+// ends up being named "bastion-ttl-check-period".
+type TTLOptions struct {
+	TTLCheckPeriod time.Duration
+	config         TTLConfig
+}
+
+// TTLConfig is the completed configuration for the TTL enforcement loop.
+type TTLConfig struct {
+	// CheckPeriod is the interval at which bastion instances are polled for
+	// TTL expiry and idle timeout.
+	CheckPeriod time.Duration
+}
+
+// AddFlags implements Flagger.AddFlags.
+func (o *TTLOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.DurationVar(&o.TTLCheckPeriod, TTLCheckPeriodFlag, defaultTTLCheckPeriod, "Period at which bastions are checked for TTL expiry and idle timeout.")
+}
+
+// Complete implements Completer.Complete.
+func (o *TTLOptions) Complete() error {
+	o.config = TTLConfig{CheckPeriod: o.TTLCheckPeriod}
+	return nil
+}
+
+// Completed returns the completed TTLConfig. Only call this after Complete
+// has run.
+func (o *TTLOptions) Completed() *TTLConfig {
+	return &o.config
+}
+
+// Apply applies the completed config to the given target config.
+func (c *TTLConfig) Apply(target *TTLConfig) {
+	target.CheckPeriod = c.CheckPeriod
+}
+
+// ttlRunnable is a manager.Runnable that periodically walks every Bastion
+// resource and enforces its TTL/idle timeout. It is registered with the
+// manager by AddTTLRunnable and runs for the lifetime of the process,
+// independent of the regular Reconcile/Delete event-driven flow.
+type ttlRunnable struct {
+	actuator    *actuator
+	checkPeriod time.Duration
+}
+
+// AddTTLRunnable registers the periodic TTL/idle-timeout enforcement loop
+// with mgr, so that enforceTTL actually gets invoked every checkPeriod
+// instead of merely existing as dead code.
+func AddTTLRunnable(mgr manager.Manager, checkPeriod time.Duration) error {
+	act, ok := NewActuator(mgr).(*actuator)
+	if !ok {
+		return fmt.Errorf("bastion actuator does not support TTL enforcement")
+	}
+
+	return mgr.Add(&ttlRunnable{actuator: act, checkPeriod: checkPeriod})
+}
+
+// Start implements manager.Runnable.
+func (r *ttlRunnable) Start(ctx context.Context) error {
+	ticker := time.NewTicker(r.checkPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.runOnce(ctx)
+		}
+	}
+}
+
+func (r *ttlRunnable) runOnce(ctx context.Context) {
+	logger := r.actuator.logger.WithValues("operation", "ttl-check")
+
+	list := &extensionsv1alpha1.BastionList{}
+	if err := r.actuator.Client().List(ctx, list); err != nil {
+		logger.Error(err, "failed to list bastions for TTL enforcement")
+		return
+	}
+
+	for i := range list.Items {
+		bastion := &list.Items[i]
+
+		cluster, err := controller.GetCluster(ctx, r.actuator.Client(), bastion.Namespace)
+		if err != nil {
+			logger.Error(err, "failed to get cluster for bastion", "bastion", client.ObjectKeyFromObject(bastion))
+			continue
+		}
+
+		if err := r.actuator.enforceTTL(ctx, bastion, cluster); err != nil {
+			logger.Error(err, "failed to enforce TTL for bastion", "bastion", client.ObjectKeyFromObject(bastion))
+		}
+	}
+}
+
+// heartbeatAnnotation carries the RFC3339 timestamp of the most recent
+// keep-alive received from gardenctl, mirroring the heartbeat mechanism
+// gardener core uses to keep a Bastion's core resource alive. It is kept in
+// sync onto the extensions Bastion by gardenlet; when absent, the instance's
+// own creation time is used instead.
+const heartbeatAnnotation = "gardener.cloud/timestamp"
+
+// bastionMaxLifetime bounds how long a bastion may live past its last
+// heartbeat (or past its creation, absent one). This mirrors the
+// gardener core Bastion's own maximum lifetime and acts as a defense-in-depth
+// backstop in case the core resource's deletion is ever missed.
+const bastionMaxLifetime = 24 * time.Hour
+
+// bastionIdleTimeout bounds how long a bastion may go without any serial
+// console activity before it is considered idle and torn down.
+const bastionIdleTimeout = time.Hour
+
+// lastSerialOffsetAnnotation and lastSerialCheckAnnotation record, between
+// successive TTL checks, how many bytes of serial console output had been
+// produced and when that was last observed. A bastion is idle once the byte
+// count stops advancing for longer than bastionIdleTimeout.
+const (
+	lastSerialOffsetAnnotation = "bastion.gcp.provider.extensions.gardener.cloud/serial-console-offset"
+	lastSerialCheckAnnotation  = "bastion.gcp.provider.extensions.gardener.cloud/serial-console-checked-at"
+)
+
+// enforceTTL is invoked periodically by ttlRunnable for every Bastion
+// resource in the cluster. It deletes the compute instance and marks the
+// Bastion as expired once the instance has either outlived its heartbeat-
+// based TTL or gone quiet on its serial console for longer than
+// bastionIdleTimeout.
+func (a *actuator) enforceTTL(ctx context.Context, bastion *extensionsv1alpha1.Bastion, cluster *controller.Cluster) error {
+	logger := a.logger.WithValues("bastion", client.ObjectKeyFromObject(bastion), "operation", "ttl-check")
+
+	serviceAccount, err := getServiceAccount(ctx, a, bastion)
+	if err != nil {
+		return fmt.Errorf("failed to get service account: %w", err)
+	}
+
+	gcpClient, err := createGCPClient(ctx, serviceAccount)
+	if err != nil {
+		return fmt.Errorf("failed to create GCP client: %w", err)
+	}
+
+	opt, err := DetermineOptions(bastion, cluster, serviceAccount.ProjectID)
+	if err != nil {
+		return fmt.Errorf("failed to determine Options: %w", err)
+	}
+
+	instance, err := getBastionInstance(ctx, gcpClient, opt)
+	if err != nil {
+		return fmt.Errorf("failed to get bastion compute instance: %w", err)
+	}
+
+	if instance == nil {
+		if IngressReady(&bastion.Status.Ingress) {
+			// the instance vanished between the regular Reconcile and this TTL
+			// check; Reconcile won't run again once the Bastion is Ready, so
+			// this loop - not Reconcile - is what has to mark it terminal.
+			logger.Info("bastion compute instance no longer exists")
+			return controller.TryUpdateStatus(ctx, retry.DefaultBackoff, a.Client(), bastion, func() error {
+				bastion.Status.LastError = &gardencorev1beta1.LastError{
+					Description: errInstanceExternallyDeleted.Error(),
+				}
+				return nil
+			})
+		}
+		return nil
+	}
+
+	if lifetimeExceeded(bastion, instance) {
+		logger.Info("bastion has exceeded its maximum lifetime, deleting compute instance")
+		return a.expireBastion(ctx, bastion, gcpClient, opt, "bastion exceeded its maximum lifetime")
+	}
+
+	idle, err := a.isSerialConsoleIdle(ctx, bastion, gcpClient, opt)
+	if err != nil {
+		return fmt.Errorf("failed to check serial console activity: %w", err)
+	}
+	if idle {
+		logger.Info("bastion has been idle for too long, deleting compute instance")
+		return a.expireBastion(ctx, bastion, gcpClient, opt, "bastion exceeded its idle timeout")
+	}
+
+	return nil
+}
+
+// expireBastion deletes the compute instance and records reason on the
+// Bastion's status, so that gardenctl stops waiting on it.
+func (a *actuator) expireBastion(ctx context.Context, bastion *extensionsv1alpha1.Bastion, gcpClient gcpclient.Interface, opt *Options, reason string) error {
+	if err := deleteComputeInstance(ctx, gcpClient, opt); err != nil {
+		return fmt.Errorf("failed to delete expired bastion compute instance: %w", err)
+	}
+
+	return controller.TryUpdateStatus(ctx, retry.DefaultBackoff, a.Client(), bastion, func() error {
+		bastion.Status.LastError = &gardencorev1beta1.LastError{Description: reason}
+		return nil
+	})
+}
+
+// lifetimeExceeded reports whether the bastion has outlived
+// bastionMaxLifetime, counted from its heartbeat annotation if present, or
+// else the compute instance's own creation time.
+func lifetimeExceeded(bastion *extensionsv1alpha1.Bastion, instance *compute.Instance) bool {
+	since, err := heartbeatOrCreationTime(bastion, instance)
+	if err != nil {
+		// without a reliable reference point we cannot claim the bastion is
+		// expired; err on the side of keeping it alive and let the operator
+		// investigate the malformed timestamp.
+		return false
+	}
+
+	return time.Since(since) > bastionMaxLifetime
+}
+
+func heartbeatOrCreationTime(bastion *extensionsv1alpha1.Bastion, instance *compute.Instance) (time.Time, error) {
+	if ts, ok := bastion.Annotations[heartbeatAnnotation]; ok {
+		return time.Parse(time.RFC3339, ts)
+	}
+
+	return time.Parse(time.RFC3339, instance.CreationTimestamp)
+}
+
+// isSerialConsoleIdle polls the instance's serial console output and
+// compares the byte offset against the one observed on the previous check
+// (persisted as annotations on the Bastion, since that is the only piece of
+// state this extension owns between TTL checks). The bastion is considered
+// idle once the offset has been unchanged for longer than
+// bastionIdleTimeout.
+func (a *actuator) isSerialConsoleIdle(ctx context.Context, bastion *extensionsv1alpha1.Bastion, gcpClient gcpclient.Interface, opt *Options) (bool, error) {
+	output, err := gcpClient.Instances().GetSerialPortOutput(opt.ProjectID, opt.Zone, opt.BastionInstanceName).Context(ctx).Do()
+	if err != nil {
+		return false, fmt.Errorf("failed to get serial console output: %w", err)
+	}
+
+	previousOffset, havePrevious := parseInt64Annotation(bastion, lastSerialOffsetAnnotation)
+	previousCheck, haveCheck := parseTimeAnnotation(bastion, lastSerialCheckAnnotation)
+
+	idle := havePrevious && haveCheck && output.Next == previousOffset && time.Since(previousCheck) > bastionIdleTimeout
+
+	if !havePrevious || output.Next != previousOffset {
+		if err := a.recordSerialConsoleActivity(ctx, bastion, output.Next); err != nil {
+			return false, err
+		}
+	}
+
+	return idle, nil
+}
+
+func (a *actuator) recordSerialConsoleActivity(ctx context.Context, bastion *extensionsv1alpha1.Bastion, offset int64) error {
+	patch := client.MergeFrom(bastion.DeepCopy())
+	if bastion.Annotations == nil {
+		bastion.Annotations = map[string]string{}
+	}
+	bastion.Annotations[lastSerialOffsetAnnotation] = strconv.FormatInt(offset, 10)
+	bastion.Annotations[lastSerialCheckAnnotation] = time.Now().UTC().Format(time.RFC3339)
+
+	return a.Client().Patch(ctx, bastion, patch)
+}
+
+func parseInt64Annotation(bastion *extensionsv1alpha1.Bastion, key string) (int64, bool) {
+	raw, ok := bastion.Annotations[key]
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseInt(raw, 10, 64)
+	return value, err == nil
+}
+
+func parseTimeAnnotation(bastion *extensionsv1alpha1.Bastion, key string) (time.Time, bool) {
+	raw, ok := bastion.Annotations[key]
+	if !ok {
+		return time.Time{}, false
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	return value, err == nil
+}